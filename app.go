@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// tenantTokenSafetyMargin 使 token 在真正过期前提前失效，避免用到最后一刻发送失败
+const tenantTokenSafetyMargin = 5 * time.Minute
+
+// tenantAccessTokenURL、imMessagesURL 声明为变量（而非常量），以便测试中替换为 httptest.Server 地址
+var (
+	tenantAccessTokenURL = "https://open.feishu.cn/open-apis/auth/v3/tenant_access_token/internal"
+	imMessagesURL        = "https://open.feishu.cn/open-apis/im/v1/messages"
+)
+
+// appConfig 携带应用机器人发送消息所需的目标与线程参数
+type appConfig struct {
+	chatID           string
+	openID           string
+	userID           string
+	email            string
+	replyInThread    bool
+	replyToMessageID string
+}
+
+// sendAsApp 使用 tenant_access_token 通过飞书 IM v1 接口发送消息（而非自定义机器人 webhook）
+// content 复用自定义机器人路径已经构建好的消息体（TextContent/PostContent/CardContent）
+// 与 webhook 路径共用 doRequestWithRetry 的退避重试逻辑（同样是有限流的 open-apis 接口）
+func sendAsApp(client *http.Client, cache Cache, retryCfg retryConfig, appID, appSecret string, cfg appConfig, msgType string, content interface{}) error {
+	token, err := getTenantAccessToken(client, cache, retryCfg, appID, appSecret)
+	if err != nil {
+		return fmt.Errorf("get tenant_access_token: %w", err)
+	}
+
+	contentJSON, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("marshal message content: %w", err)
+	}
+
+	url := imMessagesURL
+	body := map[string]interface{}{
+		"msg_type": msgType,
+		"content":  string(contentJSON),
+	}
+
+	if cfg.replyInThread && cfg.replyToMessageID != "" {
+		url = fmt.Sprintf("%s/%s/reply", imMessagesURL, cfg.replyToMessageID)
+	} else {
+		receiveIDType, receiveID, err := appTarget(cfg)
+		if err != nil {
+			return err
+		}
+		url = fmt.Sprintf("%s?receive_id_type=%s", url, receiveIDType)
+		body["receive_id"] = receiveID
+	}
+
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal send message request: %w", err)
+	}
+
+	_, respBody, err := doRequestWithRetry(client, retryCfg, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req, nil
+	})
+	if err != nil {
+		return fmt.Errorf("send app message: %w", err)
+	}
+
+	var result FeishuResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("parse send message response: %w", err)
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("request failed with code %d: %s", result.Code, result.Msg)
+	}
+
+	return nil
+}
+
+// appTarget 按优先级 chat_id > open_id > user_id > email 决定 receive_id_type 和 receive_id
+func appTarget(cfg appConfig) (string, string, error) {
+	switch {
+	case cfg.chatID != "":
+		return "chat_id", cfg.chatID, nil
+	case cfg.openID != "":
+		return "open_id", cfg.openID, nil
+	case cfg.userID != "":
+		return "user_id", cfg.userID, nil
+	case cfg.email != "":
+		return "email", cfg.email, nil
+	default:
+		return "", "", fmt.Errorf("please set one of chat_id, open_id, user_id, or email as the app bot message target")
+	}
+}
+
+// getTenantAccessToken 优先从缓存读取 tenant_access_token，未命中或已过期时才向飞书换取新 token
+// 换取请求同样经由 doRequestWithRetry 重试，避免瞬时故障直接导致整个发送失败
+func getTenantAccessToken(client *http.Client, cache Cache, retryCfg retryConfig, appID, appSecret string) (string, error) {
+	cacheKey := "tenant_access_token:" + appID
+	if token, err := cache.Get(cacheKey); err == nil {
+		return token, nil
+	}
+
+	reqBody, err := json.Marshal(map[string]string{"app_id": appID, "app_secret": appSecret})
+	if err != nil {
+		return "", fmt.Errorf("marshal tenant_access_token request: %w", err)
+	}
+
+	_, body, err := doRequestWithRetry(client, retryCfg, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", tenantAccessTokenURL, bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("request tenant_access_token: %w", err)
+	}
+
+	var tokenResp struct {
+		Code              int    `json:"code"`
+		Msg               string `json:"msg"`
+		TenantAccessToken string `json:"tenant_access_token"`
+		Expire            int    `json:"expire"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", fmt.Errorf("parse tenant_access_token response: %w", err)
+	}
+	if tokenResp.Code != 0 {
+		return "", fmt.Errorf("fetch tenant_access_token failed with code %d: %s", tokenResp.Code, tokenResp.Msg)
+	}
+
+	ttl := time.Duration(tokenResp.Expire)*time.Second - tenantTokenSafetyMargin
+	if ttl <= 0 {
+		ttl = time.Duration(tokenResp.Expire) * time.Second
+	}
+	if err := cache.Set(cacheKey, tokenResp.TenantAccessToken, ttl); err != nil {
+		fmt.Fprintf(os.Stderr, "::warning::Failed to cache tenant_access_token: %v\n", err)
+	}
+
+	return tokenResp.TenantAccessToken, nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const hourDuration = time.Hour
+
+// testRetryCfg 返回一个不会重试的配置，避免测试在预期失败路径上反复重试拖慢速度
+func testRetryCfg() retryConfig {
+	return retryConfig{maxRetries: 0, initialBackoff: time.Millisecond, maxBackoff: time.Millisecond}
+}
+
+// setTenantAccessTokenURLForTest 临时替换 tenantAccessTokenURL，返回用于恢复原值的函数
+func setTenantAccessTokenURLForTest(url string) func() {
+	original := tenantAccessTokenURL
+	tenantAccessTokenURL = url
+	return func() { tenantAccessTokenURL = original }
+}
+
+// setIMMessagesURLForTest 临时替换 imMessagesURL，返回用于恢复原值的函数
+func setIMMessagesURLForTest(url string) func() {
+	original := imMessagesURL
+	imMessagesURL = url
+	return func() { imMessagesURL = original }
+}
+
+func TestAppTargetPriority(t *testing.T) {
+	cases := []struct {
+		name         string
+		cfg          appConfig
+		wantType     string
+		wantReceived string
+	}{
+		{"chat_id wins over all", appConfig{chatID: "oc_1", openID: "ou_1", userID: "u_1", email: "a@b.com"}, "chat_id", "oc_1"},
+		{"open_id wins over user_id/email", appConfig{openID: "ou_1", userID: "u_1", email: "a@b.com"}, "open_id", "ou_1"},
+		{"user_id wins over email", appConfig{userID: "u_1", email: "a@b.com"}, "user_id", "u_1"},
+		{"email as last resort", appConfig{email: "a@b.com"}, "email", "a@b.com"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotType, gotReceived, err := appTarget(tc.cfg)
+			if err != nil {
+				t.Fatalf("appTarget returned error: %v", err)
+			}
+			if gotType != tc.wantType || gotReceived != tc.wantReceived {
+				t.Errorf("appTarget(%+v) = (%q, %q), want (%q, %q)", tc.cfg, gotType, gotReceived, tc.wantType, tc.wantReceived)
+			}
+		})
+	}
+}
+
+func TestAppTargetErrorsWhenNoneSet(t *testing.T) {
+	if _, _, err := appTarget(appConfig{}); err == nil {
+		t.Error("expected an error when no target is set, got nil")
+	}
+}
+
+func TestGetTenantAccessTokenCacheHitSkipsRequest(t *testing.T) {
+	cache := NewMemoryCache()
+	cache.Set("tenant_access_token:app1", "cached-token", hourDuration)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request should not reach the server on a cache hit")
+	}))
+	defer server.Close()
+
+	client := server.Client()
+	token, err := getTenantAccessToken(client, cache, testRetryCfg(), "app1", "secret1")
+	if err != nil {
+		t.Fatalf("getTenantAccessToken returned error: %v", err)
+	}
+	if token != "cached-token" {
+		t.Errorf("token = %q, want %q", token, "cached-token")
+	}
+}
+
+func TestGetTenantAccessTokenCacheMissFetchesAndCaches(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code":                0,
+			"msg":                 "ok",
+			"tenant_access_token": "fresh-token",
+			"expire":              7200,
+		})
+	}))
+	defer server.Close()
+	restoreURL := setTenantAccessTokenURLForTest(server.URL)
+	defer restoreURL()
+
+	cache := NewMemoryCache()
+	client := server.Client()
+	token, err := getTenantAccessToken(client, cache, testRetryCfg(), "app1", "secret1")
+	if err != nil {
+		t.Fatalf("getTenantAccessToken returned error: %v", err)
+	}
+	if token != "fresh-token" {
+		t.Errorf("token = %q, want %q", token, "fresh-token")
+	}
+	if cached, err := cache.Get("tenant_access_token:app1"); err != nil || cached != "fresh-token" {
+		t.Errorf("cache after fetch = (%q, %v), want (%q, nil)", cached, err, "fresh-token")
+	}
+}
+
+func TestGetTenantAccessTokenErrorCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": 10003,
+			"msg":  "invalid app_id",
+		})
+	}))
+	defer server.Close()
+	restoreURL := setTenantAccessTokenURLForTest(server.URL)
+	defer restoreURL()
+
+	cache := NewMemoryCache()
+	if _, err := getTenantAccessToken(server.Client(), cache, testRetryCfg(), "bad-app", "bad-secret"); err == nil {
+		t.Error("expected an error for a non-zero response code, got nil")
+	}
+}
+
+func TestSendAsAppNormalSendUsesReceiveID(t *testing.T) {
+	var gotURL, gotAuth string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "ok"})
+	}))
+	defer server.Close()
+	restoreURL := setIMMessagesURLForTest(server.URL)
+	defer restoreURL()
+
+	cache := NewMemoryCache()
+	cache.Set("tenant_access_token:app1", "tok-123", hourDuration)
+
+	cfg := appConfig{chatID: "oc_1"}
+	err := sendAsApp(server.Client(), cache, testRetryCfg(), "app1", "secret1", cfg, "text", TextContent{Text: "hi"})
+	if err != nil {
+		t.Fatalf("sendAsApp returned error: %v", err)
+	}
+	if gotURL != "/?receive_id_type=chat_id" {
+		t.Errorf("request URL = %q, want %q", gotURL, "/?receive_id_type=chat_id")
+	}
+	if gotAuth != "Bearer tok-123" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer tok-123")
+	}
+	if gotBody["receive_id"] != "oc_1" {
+		t.Errorf("receive_id = %v, want %q", gotBody["receive_id"], "oc_1")
+	}
+}
+
+func TestSendAsAppReplyInThreadUsesReplyURL(t *testing.T) {
+	var gotURL string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "msg": "ok"})
+	}))
+	defer server.Close()
+	restoreURL := setIMMessagesURLForTest(server.URL)
+	defer restoreURL()
+
+	cache := NewMemoryCache()
+	cache.Set("tenant_access_token:app1", "tok-123", hourDuration)
+
+	cfg := appConfig{chatID: "oc_1", replyInThread: true, replyToMessageID: "om_456"}
+	err := sendAsApp(server.Client(), cache, testRetryCfg(), "app1", "secret1", cfg, "text", TextContent{Text: "hi"})
+	if err != nil {
+		t.Fatalf("sendAsApp returned error: %v", err)
+	}
+	if gotURL != "/om_456/reply" {
+		t.Errorf("request URL = %q, want %q", gotURL, "/om_456/reply")
+	}
+	if _, ok := gotBody["receive_id"]; ok {
+		t.Errorf("reply request should not set receive_id, got body %v", gotBody)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrCacheMiss 表示 key 不存在或已过期
+var ErrCacheMiss = errors.New("cache: key not found or expired")
+
+// Cache 是一个带过期时间的简单键值缓存，用于跨次调用复用 tenant_access_token
+type Cache interface {
+	Get(key string) (string, error)
+	Set(key, value string, ttl time.Duration) error
+	IsExist(key string) bool
+	Delete(key string) error
+}
+
+// cacheEntry 是缓存项的序列化形式，同时被 MemoryCache 和 FileCache 使用
+type cacheEntry struct {
+	Value     string    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MemoryCache 是进程内的缓存实现，适合作为库被其他长驻进程复用
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewMemoryCache 创建一个空的进程内缓存
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]cacheEntry)}
+}
+
+func (c *MemoryCache) Get(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.ExpiresAt) {
+		return "", ErrCacheMiss
+	}
+	return entry.Value, nil
+}
+
+func (c *MemoryCache) Set(key, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry{Value: value, ExpiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (c *MemoryCache) IsExist(key string) bool {
+	_, err := c.Get(key)
+	return err == nil
+}
+
+func (c *MemoryCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	return nil
+}
+
+// FileCache 将每个 key 持久化为 dir 下的一个 JSON 文件，使 token 能跨进程（跨次 Action 调用）复用
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache 创建一个以 dir 为存储目录的文件缓存
+func NewFileCache(dir string) *FileCache {
+	return &FileCache{dir: dir}
+}
+
+// path 将 key 映射为 dir 下的文件路径。key 可能来自未经校验的输入（如 app_id），
+// 因此先做哈希，避免其中的路径分隔符或 ".." 逃出 dir
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *FileCache) Get(key string) (string, error) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrCacheMiss
+		}
+		return "", err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", err
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		return "", ErrCacheMiss
+	}
+	return entry.Value, nil
+}
+
+func (c *FileCache) Set(key, value string, ttl time.Duration) error {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cacheEntry{Value: value, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(key), data, 0o600)
+}
+
+func (c *FileCache) IsExist(key string) bool {
+	_, err := c.Get(key)
+	return err == nil
+}
+
+func (c *FileCache) Delete(key string) error {
+	err := os.Remove(c.path(key))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// newTokenCache 根据 token_cache_backend 输入选择缓存实现，默认使用文件缓存以便跨进程复用
+func newTokenCache(backend, dir string) Cache {
+	if backend == "memory" {
+		return NewMemoryCache()
+	}
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "feishu-messager-cache")
+	}
+	return NewFileCache(dir)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCacheGetSetIsExistDelete(t *testing.T, cache Cache) {
+	t.Helper()
+
+	if cache.IsExist("missing") {
+		t.Error("IsExist(missing) = true, want false")
+	}
+	if _, err := cache.Get("missing"); err != ErrCacheMiss {
+		t.Errorf("Get(missing) error = %v, want ErrCacheMiss", err)
+	}
+
+	if err := cache.Set("k", "v1", time.Hour); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	if !cache.IsExist("k") {
+		t.Error("IsExist(k) = false after Set, want true")
+	}
+	got, err := cache.Get("k")
+	if err != nil {
+		t.Fatalf("Get(k) returned error: %v", err)
+	}
+	if got != "v1" {
+		t.Errorf("Get(k) = %q, want %q", got, "v1")
+	}
+
+	if err := cache.Delete("k"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if cache.IsExist("k") {
+		t.Error("IsExist(k) = true after Delete, want false")
+	}
+
+	// Delete 一个不存在的 key 不应报错
+	if err := cache.Delete("k"); err != nil {
+		t.Errorf("Delete(missing) returned error: %v", err)
+	}
+}
+
+func TestMemoryCacheGetSetIsExistDelete(t *testing.T) {
+	testCacheGetSetIsExistDelete(t, NewMemoryCache())
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	cache := NewMemoryCache()
+	cache.Set("k", "v", -time.Second)
+
+	if _, err := cache.Get("k"); err != ErrCacheMiss {
+		t.Errorf("Get(expired) error = %v, want ErrCacheMiss", err)
+	}
+	if cache.IsExist("k") {
+		t.Error("IsExist(expired) = true, want false")
+	}
+}
+
+func TestFileCacheGetSetIsExistDelete(t *testing.T) {
+	testCacheGetSetIsExistDelete(t, NewFileCache(t.TempDir()))
+}
+
+func TestFileCacheExpiry(t *testing.T) {
+	cache := NewFileCache(t.TempDir())
+	cache.Set("k", "v", -time.Second)
+
+	if _, err := cache.Get("k"); err != ErrCacheMiss {
+		t.Errorf("Get(expired) error = %v, want ErrCacheMiss", err)
+	}
+}
+
+func TestFileCacheGetCorruptJSON(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewFileCache(dir)
+
+	// 先写一个合法 key 以确定哈希后的文件名，再用损坏内容覆盖它
+	if err := cache.Set("k", "v", time.Hour); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	path := cache.path("k")
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatalf("failed to corrupt cache file: %v", err)
+	}
+
+	if _, err := cache.Get("k"); err == nil {
+		t.Error("Get on corrupt JSON returned nil error, want a parse error")
+	}
+}
+
+func TestFileCachePathSanitizesKey(t *testing.T) {
+	cache := NewFileCache("/tmp/feishu-messager-cache-test")
+
+	maliciousKey := "tenant_access_token:../../../../etc/passwd"
+	path := cache.path(maliciousKey)
+
+	rel, err := filepath.Rel(cache.dir, path)
+	if err != nil {
+		t.Fatalf("filepath.Rel returned error: %v", err)
+	}
+	if strings.HasPrefix(rel, "..") {
+		t.Errorf("path(%q) = %q, escapes cache dir %q", maliciousKey, path, cache.dir)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// defaultEventTemplates 为常见 GitHub Actions 事件提供开箱即用的飞书消息模板（Go text/template 语法）
+var defaultEventTemplates = map[string]string{
+	"issues":        "**[{{.Repository}}] Issue {{.Action}}: {{.Title}}**\n#{{.Number}} by @{{.Author}}\n{{.URL}}\n\n{{.BodyExcerpt}}{{if .Mentions}}\ncc: {{range .Mentions}}@{{.}} {{end}}{{end}}",
+	"issue_comment": "**[{{.Repository}}] New comment on #{{.Number}} {{.Title}}**\nby @{{.Author}}\n{{.URL}}\n\n{{.BodyExcerpt}}{{if .Mentions}}\ncc: {{range .Mentions}}@{{.}} {{end}}{{end}}",
+	"pull_request":  "**[{{.Repository}}] PR {{.Action}}: {{.Title}}**\n#{{.Number}} by @{{.Author}}\n{{.URL}}\n\n{{.BodyExcerpt}}{{if .Mentions}}\ncc: {{range .Mentions}}@{{.}} {{end}}{{end}}",
+	"push":          "**[{{.Repository}}] Push to {{.Ref}} by @{{.Author}}**\n{{.URL}}\n\n{{.BodyExcerpt}}",
+	"release":       "**[{{.Repository}}] Release {{.Action}}: {{.Title}}**\nby @{{.Author}}\n{{.URL}}\n\n{{.BodyExcerpt}}",
+}
+
+const eventBodyExcerptLimit = 200
+
+// eventTemplateData 是提供给事件模板渲染的统一字段集合
+type eventTemplateData struct {
+	EventName   string
+	Action      string
+	Repository  string
+	Number      int
+	Title       string
+	URL         string
+	Author      string
+	BodyExcerpt string
+	Ref         string
+	Labels      []string
+	Mentions    []string
+}
+
+// renderGitHubEvent 读取 GitHub Actions 事件 payload，提取关键字段并渲染为飞书消息正文
+// templateFile 非空时覆盖内置模板；否则按 eventName 在 defaultEventTemplates 中查找
+func renderGitHubEvent(eventName, payloadPath, templateFile string) (string, error) {
+	raw, err := os.ReadFile(payloadPath)
+	if err != nil {
+		return "", fmt.Errorf("read event payload %q: %w", payloadPath, err)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return "", fmt.Errorf("parse event payload: %w", err)
+	}
+
+	tmplText, ok := defaultEventTemplates[eventName]
+	if templateFile != "" {
+		content, err := os.ReadFile(templateFile)
+		if err != nil {
+			return "", fmt.Errorf("read template_file %q: %w", templateFile, err)
+		}
+		tmplText = string(content)
+		ok = true
+	}
+	if !ok {
+		return "", fmt.Errorf("no built-in template for event %q, provide template_file", eventName)
+	}
+
+	tmpl, err := template.New("event").Parse(tmplText)
+	if err != nil {
+		return "", fmt.Errorf("parse template_file: %w", err)
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, extractEventData(eventName, payload)); err != nil {
+		return "", fmt.Errorf("execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// extractEventData 从原始事件 payload 中提取各事件类型通用的标题、作者、链接、正文摘要等字段
+func extractEventData(eventName string, payload map[string]interface{}) eventTemplateData {
+	data := eventTemplateData{
+		EventName: eventName,
+		Action:    stringField(payload, "action"),
+	}
+
+	if repo, ok := payload["repository"].(map[string]interface{}); ok {
+		data.Repository = stringField(repo, "full_name")
+	}
+
+	var subject map[string]interface{}
+	switch eventName {
+	case "issues":
+		subject, _ = payload["issue"].(map[string]interface{})
+	case "issue_comment":
+		subject, _ = payload["issue"].(map[string]interface{})
+		if comment, ok := payload["comment"].(map[string]interface{}); ok {
+			data.URL = stringField(comment, "html_url")
+			data.Author = stringField(userOf(comment), "login")
+			data.BodyExcerpt = excerpt(stringField(comment, "body"))
+		}
+	case "pull_request":
+		subject, _ = payload["pull_request"].(map[string]interface{})
+	case "release":
+		subject, _ = payload["release"].(map[string]interface{})
+	case "push":
+		data.Ref = stringField(payload, "ref")
+		data.URL = stringField(payload, "compare")
+		if pusher, ok := payload["pusher"].(map[string]interface{}); ok {
+			data.Author = stringField(pusher, "name")
+		}
+		if headCommit, ok := payload["head_commit"].(map[string]interface{}); ok {
+			data.Title = stringField(headCommit, "message")
+			data.BodyExcerpt = excerpt(data.Title)
+		}
+	}
+
+	if subject != nil {
+		data.Title = stringField(subject, "title")
+		if data.URL == "" {
+			data.URL = stringField(subject, "html_url")
+		}
+		if data.Author == "" {
+			data.Author = stringField(userOf(subject), "login")
+		}
+		if data.BodyExcerpt == "" {
+			data.BodyExcerpt = excerpt(stringField(subject, "body"))
+		}
+		if number, ok := subject["number"].(float64); ok {
+			data.Number = int(number)
+		}
+		data.Labels = labelsOf(subject)
+		data.Mentions = append(data.Mentions, loginsOf(subject["assignees"])...)
+		data.Mentions = append(data.Mentions, loginsOf(subject["requested_reviewers"])...)
+	}
+
+	return data
+}
+
+// stringField 安全地读取 map 中的字符串字段，字段不存在或类型不符时返回空字符串
+func stringField(m map[string]interface{}, key string) string {
+	if m == nil {
+		return ""
+	}
+	if v, ok := m[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// userOf 返回 GitHub 对象（issue/pull_request/comment 等）内嵌的 user 对象
+func userOf(m map[string]interface{}) map[string]interface{} {
+	user, _ := m["user"].(map[string]interface{})
+	return user
+}
+
+// labelsOf 提取 issue/pull_request 的 labels 名称列表
+func labelsOf(subject map[string]interface{}) []string {
+	rawLabels, ok := subject["labels"].([]interface{})
+	if !ok {
+		return nil
+	}
+	labels := make([]string, 0, len(rawLabels))
+	for _, raw := range rawLabels {
+		if label, ok := raw.(map[string]interface{}); ok {
+			if name := stringField(label, "name"); name != "" {
+				labels = append(labels, name)
+			}
+		}
+	}
+	return labels
+}
+
+// loginsOf 从 assignees/requested_reviewers 这类用户数组中提取 login 列表
+func loginsOf(raw interface{}) []string {
+	users, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+	logins := make([]string, 0, len(users))
+	for _, raw := range users {
+		if user, ok := raw.(map[string]interface{}); ok {
+			if login := stringField(user, "login"); login != "" {
+				logins = append(logins, login)
+			}
+		}
+	}
+	return logins
+}
+
+// excerpt 将正文截断到 eventBodyExcerptLimit 个字符，避免卡片消息过长
+func excerpt(body string) string {
+	body = strings.TrimSpace(body)
+	runes := []rune(body)
+	if len(runes) <= eventBodyExcerptLimit {
+		return body
+	}
+	return string(runes[:eventBodyExcerptLimit]) + "..."
+}
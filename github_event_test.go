@@ -0,0 +1,129 @@
+package main
+
+import "testing"
+
+func TestExtractEventDataIssues(t *testing.T) {
+	payload := map[string]interface{}{
+		"action": "opened",
+		"repository": map[string]interface{}{
+			"full_name": "stvenx/feishu-messager",
+		},
+		"issue": map[string]interface{}{
+			"title":    "Something broke",
+			"html_url": "https://github.com/stvenx/feishu-messager/issues/1",
+			"number":   float64(1),
+			"body":     "details here",
+			"user":     map[string]interface{}{"login": "alice"},
+			"labels": []interface{}{
+				map[string]interface{}{"name": "bug"},
+			},
+			"assignees": []interface{}{
+				map[string]interface{}{"login": "bob"},
+			},
+		},
+	}
+
+	data := extractEventData("issues", payload)
+
+	if data.Repository != "stvenx/feishu-messager" {
+		t.Errorf("Repository = %q", data.Repository)
+	}
+	if data.Action != "opened" {
+		t.Errorf("Action = %q", data.Action)
+	}
+	if data.Title != "Something broke" {
+		t.Errorf("Title = %q", data.Title)
+	}
+	if data.URL != "https://github.com/stvenx/feishu-messager/issues/1" {
+		t.Errorf("URL = %q", data.URL)
+	}
+	if data.Number != 1 {
+		t.Errorf("Number = %d", data.Number)
+	}
+	if data.Author != "alice" {
+		t.Errorf("Author = %q", data.Author)
+	}
+	if len(data.Labels) != 1 || data.Labels[0] != "bug" {
+		t.Errorf("Labels = %v", data.Labels)
+	}
+	if len(data.Mentions) != 1 || data.Mentions[0] != "bob" {
+		t.Errorf("Mentions = %v", data.Mentions)
+	}
+}
+
+// Regression test: the comment's own html_url/author/body must survive, not be
+// overwritten by the parent issue's fields.
+func TestExtractEventDataIssueCommentKeepsCommentURL(t *testing.T) {
+	payload := map[string]interface{}{
+		"repository": map[string]interface{}{
+			"full_name": "stvenx/feishu-messager",
+		},
+		"issue": map[string]interface{}{
+			"title":    "Something broke",
+			"html_url": "https://github.com/stvenx/feishu-messager/issues/1",
+			"number":   float64(1),
+		},
+		"comment": map[string]interface{}{
+			"html_url": "https://github.com/stvenx/feishu-messager/issues/1#issuecomment-1",
+			"body":     "a reply",
+			"user":     map[string]interface{}{"login": "carol"},
+		},
+	}
+
+	data := extractEventData("issue_comment", payload)
+
+	if data.URL != "https://github.com/stvenx/feishu-messager/issues/1#issuecomment-1" {
+		t.Errorf("URL = %q, want the comment's own html_url, not the issue's", data.URL)
+	}
+	if data.Author != "carol" {
+		t.Errorf("Author = %q, want the commenter", data.Author)
+	}
+	if data.BodyExcerpt != "a reply" {
+		t.Errorf("BodyExcerpt = %q, want the comment body", data.BodyExcerpt)
+	}
+	if data.Title != "Something broke" {
+		t.Errorf("Title = %q, want the parent issue's title", data.Title)
+	}
+}
+
+func TestExtractEventDataPush(t *testing.T) {
+	payload := map[string]interface{}{
+		"ref":     "refs/heads/main",
+		"compare": "https://github.com/stvenx/feishu-messager/compare/a...b",
+		"pusher":  map[string]interface{}{"name": "dave"},
+		"head_commit": map[string]interface{}{
+			"message": "fix: something",
+		},
+	}
+
+	data := extractEventData("push", payload)
+
+	if data.Ref != "refs/heads/main" {
+		t.Errorf("Ref = %q", data.Ref)
+	}
+	if data.Author != "dave" {
+		t.Errorf("Author = %q", data.Author)
+	}
+	if data.URL != "https://github.com/stvenx/feishu-messager/compare/a...b" {
+		t.Errorf("URL = %q", data.URL)
+	}
+	if data.BodyExcerpt != "fix: something" {
+		t.Errorf("BodyExcerpt = %q", data.BodyExcerpt)
+	}
+}
+
+func TestExcerptTruncatesLongBody(t *testing.T) {
+	long := make([]byte, eventBodyExcerptLimit+50)
+	for i := range long {
+		long[i] = 'a'
+	}
+
+	got := excerpt(string(long))
+	runes := []rune(got)
+	if len(runes) != eventBodyExcerptLimit+len("...") {
+		t.Errorf("excerpt length = %d, want %d", len(runes), eventBodyExcerptLimit+len("..."))
+	}
+	if got[len(got)-3:] != "..." {
+		t.Errorf("excerpt = %q, want to end with ...", got)
+	}
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,8 +19,10 @@ const (
 )
 
 type FeishuMessage struct {
-	MsgType string      `json:"msg_type"`
-	Content interface{} `json:"content"`
+	MsgType   string      `json:"msg_type"`
+	Content   interface{} `json:"content"`
+	Timestamp string      `json:"timestamp,omitempty"`
+	Sign      string      `json:"sign,omitempty"`
 }
 
 type TextContent struct {
@@ -51,16 +56,41 @@ func main() {
 	msgType := getEnv("msg_type")
 	userMaps := getEnv("user_maps")
 	assigneesJSON := getEnv("assignees")
+	botSecret := getEnv("bot_secret")
+
+	// GitHub 事件转发模式：默认取 Actions 运行时注入的 GITHUB_EVENT_NAME / GITHUB_EVENT_PATH
+	eventName := getEnv("event_name")
+	if eventName == "" {
+		eventName = os.Getenv("GITHUB_EVENT_NAME")
+	}
+	eventPayloadPath := getEnv("event_payload_path")
+	if eventPayloadPath == "" {
+		eventPayloadPath = os.Getenv("GITHUB_EVENT_PATH")
+	}
+	templateFile := getEnv("template_file")
+
+	// 应用机器人（app bot）发送模式：提供 app_id/app_secret 时走 tenant_access_token + IM v1 接口
+	appID := getEnv("app_id")
+	appSecret := getEnv("app_secret")
+	appCfg := appConfig{
+		chatID:           getEnv("chat_id"),
+		openID:           getEnv("open_id"),
+		userID:           getEnv("user_id"),
+		email:            getEnv("email"),
+		replyInThread:    getEnv("reply_in_thread") == "true",
+		replyToMessageID: getEnv("reply_to_message_id"),
+	}
+	useAppBot := appID != "" && appSecret != ""
 
 	// 验证必需参数
-	if botToken == "" {
-		fmt.Fprintf(os.Stderr, "::error::Please set the BOT_TOKEN secret.\n")
+	if !useAppBot && botToken == "" {
+		fmt.Fprintf(os.Stderr, "::error::Please set the BOT_TOKEN secret, or app_id/app_secret to send as an app bot.\n")
 		os.Exit(1)
 	}
 
-	if postMessage == "" && messageFile == "" {
-		fmt.Fprintf(os.Stderr, "::error::Please set the post message or a file containing the message.\n")
-		os.Exit(1)
+	// 设置默认消息类型
+	if msgType == "" {
+		msgType = "text"
 	}
 
 	// 处理消息文件
@@ -73,30 +103,98 @@ func main() {
 		postMessage = string(content)
 	}
 
+	// 处理 GitHub 事件转发：未显式提供 post_message/message_file 时，尝试从 Actions 事件 payload 自动渲染
+	if postMessage == "" && eventPayloadPath != "" {
+		rendered, err := renderGitHubEvent(eventName, eventPayloadPath, templateFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "::error::Failed to render GitHub event message: %v\n", err)
+			os.Exit(1)
+		}
+		if msgType != "post" && msgType != "interactive" {
+			rendered = applyAtMentions(rendered, userMaps)
+		}
+		postMessage = rendered
+	}
+
+	if postMessage == "" {
+		fmt.Fprintf(os.Stderr, "::error::Please set the post message, a file containing the message, or run this action in a GitHub Actions event context.\n")
+		os.Exit(1)
+	}
+
 	// 处理 @用户功能
-	if assigneesJSON != "" && userMaps != "" {
+	// post 类型的 @提及由 renderPost 直接从 markdown 中解析，interactive 类型的 postMessage 是卡片 JSON，
+	// 两者都不能被预先拼接的 <at> 标签污染
+	if msgType != "post" && msgType != "interactive" && assigneesJSON != "" && userMaps != "" {
 		atUsers := parseUsers(userMaps, assigneesJSON)
 		if atUsers != "" {
 			postMessage = atUsers + postMessage
 		}
 	}
 
-	// 设置默认消息类型
-	if msgType == "" {
-		msgType = "text"
-	}
-
 	// 验证消息类型
-	if msgType != "text" && msgType != "markdown" {
-		fmt.Fprintf(os.Stderr, "::error::Unsupported MSG_TYPE: %s. Supported types: text, markdown\n", msgType)
+	switch msgType {
+	case "text", "markdown", "post", "interactive":
+	default:
+		fmt.Fprintf(os.Stderr, "::error::Unsupported MSG_TYPE: %s. Supported types: text, markdown, post, interactive\n", msgType)
 		os.Exit(1)
 	}
 
 	// 构建请求体
-	var requestBody FeishuMessage
-	requestBody.MsgType = "text" // 飞书目前都使用 text 类型，markdown 语法在 text 中支持
-	requestBody.Content = TextContent{
-		Text: postMessage,
+	postLocale := getEnv("post_locale")
+	if postLocale == "" {
+		postLocale = "zh_cn"
+	}
+	actualMsgType, content, err := buildMessageContent(msgType, postMessage, userMaps, postLocale)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "::error::%v\n", err)
+		os.Exit(1)
+	}
+	requestBody := FeishuMessage{MsgType: actualMsgType, Content: content}
+
+	// 解析重试相关配置（webhook 与应用机器人路径共用）
+	retryCfg := retryConfig{
+		maxRetries:     3,
+		initialBackoff: 500 * time.Millisecond,
+		maxBackoff:     10 * time.Second,
+	}
+	if v := getEnv("max_retries"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			retryCfg.maxRetries = n
+		}
+	}
+	if v := getEnv("initial_backoff"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			retryCfg.initialBackoff = d
+		}
+	}
+	if v := getEnv("max_backoff"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			retryCfg.maxBackoff = d
+		}
+	}
+
+	// 应用机器人路径：使用 tenant_access_token 调用 IM v1 接口，而非自定义机器人 webhook
+	if useAppBot {
+		cache := newTokenCache(getEnv("token_cache_backend"), getEnv("token_cache_dir"))
+		appClient := &http.Client{Timeout: 30 * time.Second}
+		if err := sendAsApp(appClient, cache, retryCfg, appID, appSecret, appCfg, requestBody.MsgType, requestBody.Content); err != nil {
+			fmt.Fprintf(os.Stderr, "::error::%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("::notice::Message sent successfully")
+		return
+	}
+
+	// 如果配置了签名校验密钥，附加 timestamp 和 sign
+	if botSecret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		sign, err := genSign(timestamp, botSecret)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "::error::Failed to generate signature: %v\n", err)
+			os.Exit(1)
+		}
+		requestBody.Timestamp = timestamp
+		requestBody.Sign = sign
 	}
 
 	// 序列化请求体
@@ -121,56 +219,29 @@ func main() {
 	fmt.Println("================================")
 	fmt.Println()
 
-	// 发送 HTTP 请求
+	// 发送 HTTP 请求（失败时按配置退避重试）
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 	}
 
-	req, err := http.NewRequest("POST", webhookURL, strings.NewReader(string(jsonData)))
+	feishuResp, err := sendWithRetry(client, webhookURL, jsonData, retryCfg)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "::error::Failed to create request: %v\n", err)
+		fmt.Fprintf(os.Stderr, "::error::%v\n", err)
 		os.Exit(1)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "::error::Failed to send request: %v\n", err)
-		os.Exit(1)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "::error::Failed to read response: %v\n", err)
-		os.Exit(1)
-	}
-
-	// 调试输出响应
-	fmt.Println("=== Debug: Response Information ===")
-	fmt.Printf("HTTP Status Code: %d\n", resp.StatusCode)
-	fmt.Println("Response Body:")
-	var prettyResp bytes.Buffer
-	json.Indent(&prettyResp, responseBody, "", "  ")
-	fmt.Println(prettyResp.String())
-	fmt.Println("================================")
-
-	// 解析响应
-	var feishuResp FeishuResponse
-	if err := json.Unmarshal(responseBody, &feishuResp); err != nil {
-		fmt.Fprintf(os.Stderr, "::error::Failed to parse response: %v\n", err)
-		os.Exit(1)
-	}
+	fmt.Printf("::notice::Message sent successfully (code %d: %s)\n", feishuResp.Code, feishuResp.Msg)
+}
 
-	// 检查响应
-	if resp.StatusCode != http.StatusOK || feishuResp.Code != 0 {
-		fmt.Fprintf(os.Stderr, "::error::Request failed with code %d: %s\n", feishuResp.Code, feishuResp.Msg)
-		os.Exit(1)
+// genSign 计算飞书自定义机器人的签名校验值
+// 算法：以 timestamp+"\n"+secret 作为 key，对空字符串做 HMAC-SHA256，再 base64 编码
+func genSign(timestamp, secret string) (string, error) {
+	key := timestamp + "\n" + secret
+	h := hmac.New(sha256.New, []byte(key))
+	if _, err := h.Write([]byte("")); err != nil {
+		return "", err
 	}
-
-	fmt.Println("::notice::Message sent successfully")
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
 }
 
 // parseUsers 解析用户映射，生成 @用户的标签
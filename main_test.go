@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestGenSign(t *testing.T) {
+	got, err := genSign("1700000000", "mysecret")
+	if err != nil {
+		t.Fatalf("genSign returned error: %v", err)
+	}
+
+	want := "Jp33/xXhCipDEpjyHvEyc7mRSyXWHbNz6J8+C3qQKNo="
+	if got != want {
+		t.Errorf("genSign(%q, %q) = %q, want %q", "1700000000", "mysecret", got, want)
+	}
+}
+
+func TestGenSignDifferentTimestampsDiffer(t *testing.T) {
+	sign1, err := genSign("1700000000", "mysecret")
+	if err != nil {
+		t.Fatalf("genSign returned error: %v", err)
+	}
+	sign2, err := genSign("1700000001", "mysecret")
+	if err != nil {
+		t.Fatalf("genSign returned error: %v", err)
+	}
+	if sign1 == sign2 {
+		t.Errorf("expected different signatures for different timestamps, got the same: %q", sign1)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PostContent 是飞书富文本（post）消息体，key 为语言区域（如 zh_cn、en_us）
+type PostContent map[string]PostLocale
+
+// PostLocale 对应某个语言区域下的标题与段落内容
+type PostLocale struct {
+	Title   string          `json:"title"`
+	Content []PostParagraph `json:"content"`
+}
+
+// PostParagraph 是 post 消息的一个段落，由若干个带标签的元素组成
+type PostParagraph []PostElement
+
+// PostElement 是 post 段落中的一个富文本元素：text、a（链接）、at（@用户）或 img（图片）
+type PostElement struct {
+	Tag      string   `json:"tag"`
+	Text     string   `json:"text,omitempty"`
+	Style    []string `json:"style,omitempty"`
+	UserID   string   `json:"user_id,omitempty"`
+	Href     string   `json:"href,omitempty"`
+	ImageKey string   `json:"image_key,omitempty"`
+}
+
+// CardContent 是飞书 interactive 卡片消息体，直接透传调用方提供的卡片 JSON
+type CardContent = map[string]interface{}
+
+var (
+	postImageRe = regexp.MustCompile(`!\[([^\]]*)\]\(([^)]+)\)`)
+	postLinkRe  = regexp.MustCompile(`\[([^\]]*)\]\(([^)]+)\)`)
+	postAtRe    = regexp.MustCompile(`@(\S+)`)
+	postBoldRe  = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	postLineRe  = regexp.MustCompile(strings.Join([]string{postImageRe.String(), postLinkRe.String(), postAtRe.String(), postBoldRe.String()}, "|"))
+)
+
+// renderPost 将一段轻量 markdown（标题、加粗、链接、图片、@提及）转换为飞书 post 富文本结构
+// userMaps 格式同 parseUsers：stvenx:ou_xxxx,user1:ou_yyyy，用于将 @username 解析为 at 元素
+func renderPost(markdown, userMaps string) PostLocale {
+	lines := strings.Split(markdown, "\n")
+
+	var title string
+	if len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[0]), "# ") {
+		title = strings.TrimPrefix(strings.TrimSpace(lines[0]), "# ")
+		lines = lines[1:]
+	}
+
+	userIDs := parseUserMaps(userMaps)
+
+	var paragraphs []PostParagraph
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		paragraphs = append(paragraphs, renderPostLine(line, userIDs))
+	}
+
+	return PostLocale{
+		Title:   title,
+		Content: paragraphs,
+	}
+}
+
+// renderPostLine 解析单行 markdown，依次识别 图片、链接、@提及、加粗，其余部分作为纯文本
+func renderPostLine(line string, userIDs map[string]string) PostParagraph {
+	var paragraph PostParagraph
+	pos := 0
+	for _, loc := range postLineRe.FindAllStringSubmatchIndex(line, -1) {
+		if loc[0] > pos {
+			paragraph = append(paragraph, PostElement{Tag: "text", Text: line[pos:loc[0]]})
+		}
+
+		switch {
+		case loc[2] >= 0: // 图片 ![alt](image_key)
+			paragraph = append(paragraph, PostElement{Tag: "img", ImageKey: line[loc[4]:loc[5]]})
+		case loc[6] >= 0: // 链接 [text](href)
+			paragraph = append(paragraph, PostElement{Tag: "a", Text: line[loc[6]:loc[7]], Href: line[loc[8]:loc[9]]})
+		case loc[10] >= 0: // @提及
+			username := line[loc[10]:loc[11]]
+			if userID, ok := userIDs[username]; ok {
+				paragraph = append(paragraph, PostElement{Tag: "at", UserID: userID})
+			} else {
+				paragraph = append(paragraph, PostElement{Tag: "text", Text: "@" + username})
+			}
+		case loc[12] >= 0: // 加粗 **text**
+			paragraph = append(paragraph, PostElement{Tag: "text", Text: line[loc[12]:loc[13]], Style: []string{"bold"}})
+		}
+
+		pos = loc[1]
+	}
+	if pos < len(line) {
+		paragraph = append(paragraph, PostElement{Tag: "text", Text: line[pos:]})
+	}
+
+	return paragraph
+}
+
+// buildMessageContent 根据 msg_type 构建飞书消息体的 (msg_type, content) 二元组：
+// post 调用 renderPost 渲染 markdown，interactive 将 postMessage 解析为卡片 JSON 原样透传，
+// 其余（text/markdown）走既有的 TextContent 路径
+func buildMessageContent(msgType, postMessage, userMaps, postLocale string) (string, interface{}, error) {
+	switch msgType {
+	case "post":
+		return "post", PostContent{
+			postLocale: renderPost(postMessage, userMaps),
+		}, nil
+	case "interactive":
+		var card CardContent
+		if err := json.Unmarshal([]byte(postMessage), &card); err != nil {
+			return "", nil, fmt.Errorf("failed to parse interactive card JSON: %w", err)
+		}
+		return "interactive", card, nil
+	default:
+		// text / markdown：飞书目前都使用 text 类型，markdown 语法在 text 中支持
+		return "text", TextContent{Text: postMessage}, nil
+	}
+}
+
+// applyAtMentions 将文本中的 @username 标记替换为飞书 text/markdown 消息可识别的 <at> 标签
+// 未能在 userMaps 中找到对应 open_id 的 @username 保持原样
+func applyAtMentions(text, userMaps string) string {
+	if userMaps == "" {
+		return text
+	}
+	userIDs := parseUserMaps(userMaps)
+	return postAtRe.ReplaceAllStringFunc(text, func(match string) string {
+		username := strings.TrimPrefix(match, "@")
+		if userID, ok := userIDs[username]; ok {
+			return fmt.Sprintf(`<at user_id="%s">%s</at>`, userID, username)
+		}
+		return match
+	})
+}
+
+// parseUserMaps 将 user_maps 输入（stvenx:ou_xxxx,user1:ou_yyyy）解析为 login -> open_id 映射
+func parseUserMaps(userMaps string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(userMaps, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package main
+
+import "testing"
+
+func TestRenderPostTitleAndParagraphs(t *testing.T) {
+	locale := renderPost("# Hello\nplain text", "")
+
+	if locale.Title != "Hello" {
+		t.Errorf("Title = %q, want %q", locale.Title, "Hello")
+	}
+	if len(locale.Content) != 1 {
+		t.Fatalf("Content has %d paragraphs, want 1", len(locale.Content))
+	}
+	if got := locale.Content[0]; len(got) != 1 || got[0].Tag != "text" || got[0].Text != "plain text" {
+		t.Errorf("paragraph = %+v, want a single text element \"plain text\"", got)
+	}
+}
+
+func TestRenderPostLineElements(t *testing.T) {
+	userIDs := map[string]string{"stvenx": "ou_123"}
+	paragraph := renderPostLine("hi ![alt](img1) [go](http://x) @stvenx @nobody **bold**", userIDs)
+
+	var gotTags []string
+	for _, el := range paragraph {
+		gotTags = append(gotTags, el.Tag)
+	}
+	wantTags := []string{"text", "img", "text", "a", "text", "at", "text", "text", "text", "text"}
+	if len(gotTags) != len(wantTags) {
+		t.Fatalf("tags = %v, want %v", gotTags, wantTags)
+	}
+	for i := range wantTags {
+		if gotTags[i] != wantTags[i] {
+			t.Errorf("tag[%d] = %q, want %q (full: %v)", i, gotTags[i], wantTags[i], gotTags)
+		}
+	}
+
+	for _, el := range paragraph {
+		switch el.Tag {
+		case "img":
+			if el.ImageKey != "img1" {
+				t.Errorf("image key = %q, want %q", el.ImageKey, "img1")
+			}
+		case "a":
+			if el.Text != "go" || el.Href != "http://x" {
+				t.Errorf("link = %+v, want text=go href=http://x", el)
+			}
+		case "at":
+			if el.UserID != "ou_123" {
+				t.Errorf("at user_id = %q, want %q", el.UserID, "ou_123")
+			}
+		}
+	}
+}
+
+func TestRenderPostLineUnmappedMentionFallsBackToText(t *testing.T) {
+	paragraph := renderPostLine("cc @nobody", map[string]string{})
+	for _, el := range paragraph {
+		if el.Tag == "at" {
+			t.Errorf("unmapped @nobody should not become an at element: %+v", paragraph)
+		}
+	}
+}
+
+func TestBuildMessageContentInteractiveParsesCardJSON(t *testing.T) {
+	actualMsgType, content, err := buildMessageContent("interactive", `{"header":{"title":"hi"}}`, "", "zh_cn")
+	if err != nil {
+		t.Fatalf("buildMessageContent returned error: %v", err)
+	}
+	if actualMsgType != "interactive" {
+		t.Errorf("actualMsgType = %q, want %q", actualMsgType, "interactive")
+	}
+	card, ok := content.(CardContent)
+	if !ok {
+		t.Fatalf("content has type %T, want CardContent", content)
+	}
+	header, ok := card["header"].(map[string]interface{})
+	if !ok || header["title"] != "hi" {
+		t.Errorf("card content = %+v, want header.title = hi", card)
+	}
+}
+
+func TestBuildMessageContentInteractiveRejectsNonJSON(t *testing.T) {
+	// Regression: a <at> tag prepended by the legacy @-mention path must not
+	// reach here, but if it does the error must be reported, not ignored.
+	_, _, err := buildMessageContent("interactive", `<at user_id="ou_1">name</at>{"header":{}}`, "", "zh_cn")
+	if err == nil {
+		t.Fatal("expected an error for non-JSON interactive content, got nil")
+	}
+}
+
+func TestBuildMessageContentPost(t *testing.T) {
+	actualMsgType, content, err := buildMessageContent("post", "# T\nbody", "", "zh_cn")
+	if err != nil {
+		t.Fatalf("buildMessageContent returned error: %v", err)
+	}
+	if actualMsgType != "post" {
+		t.Errorf("actualMsgType = %q, want %q", actualMsgType, "post")
+	}
+	post, ok := content.(PostContent)
+	if !ok {
+		t.Fatalf("content has type %T, want PostContent", content)
+	}
+	if post["zh_cn"].Title != "T" {
+		t.Errorf("title = %q, want %q", post["zh_cn"].Title, "T")
+	}
+}
+
+func TestBuildMessageContentTextDefault(t *testing.T) {
+	actualMsgType, content, err := buildMessageContent("markdown", "hello", "", "zh_cn")
+	if err != nil {
+		t.Fatalf("buildMessageContent returned error: %v", err)
+	}
+	if actualMsgType != "text" {
+		t.Errorf("actualMsgType = %q, want %q", actualMsgType, "text")
+	}
+	text, ok := content.(TextContent)
+	if !ok || text.Text != "hello" {
+		t.Errorf("content = %+v, want TextContent{Text: hello}", content)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// feishuThrottleCode 是飞书返回的限流错误码（超过 QPS/分钟调用次数限制）
+const feishuThrottleCode = 9499
+
+// retryConfig 控制请求失败后的重试次数与退避时长
+type retryConfig struct {
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// sendWithRetry 发送 webhook 请求，在网络错误、5xx、429 或飞书限流错误码时按指数退避重试
+// 重试耗尽后返回最后一次的错误；成功时返回解析后的飞书响应
+func sendWithRetry(client *http.Client, webhookURL string, jsonData []byte, cfg retryConfig) (*FeishuResponse, error) {
+	resp, responseBody, err := doRequestWithRetry(client, cfg, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", webhookURL, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var feishuResp FeishuResponse
+	if err := json.Unmarshal(responseBody, &feishuResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || feishuResp.Code != 0 {
+		return nil, fmt.Errorf("request failed with code %d: %s", feishuResp.Code, feishuResp.Msg)
+	}
+
+	return &feishuResp, nil
+}
+
+// doRequestWithRetry 是 webhook 与应用机器人（IM v1）请求共用的退避重试核心：
+// 在网络错误、5xx、429 或飞书限流错误码（通过响应体里的 code 字段探测）时重试，
+// 重试耗尽或遇到不可重试的失败时返回最后一次的响应体供调用方自行解析
+func doRequestWithRetry(client *http.Client, cfg retryConfig, buildReq func() (*http.Request, error)) (*http.Response, []byte, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= cfg.maxRetries; attempt++ {
+		if attempt > 0 {
+			fmt.Printf("::warning::Retrying request (attempt %d/%d)\n", attempt+1, cfg.maxRetries+1)
+		}
+
+		req, err := buildReq()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to send request: %w", err)
+			fmt.Printf("::warning::Attempt %d failed: %v\n", attempt+1, err)
+			if attempt == cfg.maxRetries || !isRetryableNetErr(err) {
+				return nil, nil, lastErr
+			}
+			backoffAndLog(cfg, attempt, 0)
+			continue
+		}
+
+		responseBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		// 调试输出响应
+		fmt.Println("=== Debug: Response Information ===")
+		fmt.Printf("HTTP Status Code: %d\n", resp.StatusCode)
+		fmt.Println("Response Body:")
+		var prettyResp bytes.Buffer
+		json.Indent(&prettyResp, responseBody, "", "  ")
+		fmt.Println(prettyResp.String())
+		fmt.Println("================================")
+
+		var probe struct {
+			Code int `json:"code"`
+		}
+		json.Unmarshal(responseBody, &probe)
+
+		if !isRetryableResponse(resp.StatusCode, probe.Code) {
+			return resp, responseBody, nil
+		}
+
+		lastErr = fmt.Errorf("request failed with HTTP %d, code %d", resp.StatusCode, probe.Code)
+		if attempt == cfg.maxRetries {
+			return resp, responseBody, nil
+		}
+
+		fmt.Printf("::warning::Attempt %d failed (HTTP %d, code %d), will retry\n", attempt+1, resp.StatusCode, probe.Code)
+		backoffAndLog(cfg, attempt, parseRetryAfter(resp.Header.Get("Retry-After")))
+	}
+
+	return nil, nil, lastErr
+}
+
+// isRetryableNetErr 判断 client.Do 返回的错误是否属于可重试的网络错误（超时、连接失败等）
+func isRetryableNetErr(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isRetryableResponse 判断 HTTP 状态码/飞书错误码是否表示限流或服务端瞬时故障
+func isRetryableResponse(statusCode, feishuCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500 || feishuCode == feishuThrottleCode
+}
+
+// backoffAndLog 按指数退避（附 ±20% 抖动）休眠，优先使用服务端给出的 Retry-After
+func backoffAndLog(cfg retryConfig, attempt int, retryAfter time.Duration) {
+	backoff := retryAfter
+	if backoff <= 0 {
+		backoff = computeBackoff(cfg.initialBackoff, cfg.maxBackoff, attempt)
+	}
+	fmt.Printf("::notice::Backing off %s before next attempt\n", backoff)
+	time.Sleep(backoff)
+}
+
+// computeBackoff 计算 min(maxBackoff, initialBackoff*2^attempt) 并叠加 ±20% 抖动
+func computeBackoff(initialBackoff, maxBackoff time.Duration, attempt int) time.Duration {
+	backoff := initialBackoff * time.Duration(1<<uint(attempt))
+	if backoff <= 0 || backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	jitter := 0.8 + 0.4*rand.Float64() // [0.8, 1.2)
+	return time.Duration(float64(backoff) * jitter)
+}
+
+// parseRetryAfter 解析 Retry-After 响应头（秒数或 HTTP-date），解析失败时返回 0
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestComputeBackoffExponentialGrowthWithinJitter(t *testing.T) {
+	initial := 500 * time.Millisecond
+	max := 10 * time.Second
+
+	for attempt := 0; attempt < 4; attempt++ {
+		expected := initial * time.Duration(1<<uint(attempt))
+		lo := time.Duration(float64(expected) * 0.8)
+		hi := time.Duration(float64(expected) * 1.2)
+		for i := 0; i < 20; i++ {
+			got := computeBackoff(initial, max, attempt)
+			if got < lo || got > hi {
+				t.Errorf("computeBackoff(attempt=%d) = %s, want within [%s, %s]", attempt, got, lo, hi)
+			}
+		}
+	}
+}
+
+func TestComputeBackoffCapsAtMaxBackoff(t *testing.T) {
+	initial := 500 * time.Millisecond
+	max := 2 * time.Second
+
+	// attempt 大到足以让未封顶的指数退避远超 maxBackoff
+	hi := time.Duration(float64(max) * 1.2)
+	for i := 0; i < 20; i++ {
+		got := computeBackoff(initial, max, 10)
+		if got > hi {
+			t.Errorf("computeBackoff with large attempt = %s, want capped around maxBackoff %s (+20%% jitter)", got, max)
+		}
+	}
+}
+
+func TestParseRetryAfterSeconds(t *testing.T) {
+	got := parseRetryAfter("5")
+	if got != 5*time.Second {
+		t.Errorf("parseRetryAfter(%q) = %s, want 5s", "5", got)
+	}
+}
+
+func TestParseRetryAfterEmptyOrInvalid(t *testing.T) {
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("parseRetryAfter(\"\") = %s, want 0", got)
+	}
+	if got := parseRetryAfter("not-a-valid-value"); got != 0 {
+		t.Errorf("parseRetryAfter(invalid) = %s, want 0", got)
+	}
+}
+
+func TestParseRetryAfterHTTPDate(t *testing.T) {
+	future := time.Now().Add(1 * time.Hour).UTC()
+	header := future.Format(http.TimeFormat)
+
+	got := parseRetryAfter(header)
+	if got <= 0 || got > time.Hour {
+		t.Errorf("parseRetryAfter(%q) = %s, want a positive duration close to 1h", header, got)
+	}
+}
+
+func TestParseRetryAfterPastHTTPDateReturnsZero(t *testing.T) {
+	past := time.Now().Add(-1 * time.Hour).UTC()
+	header := past.Format(http.TimeFormat)
+
+	if got := parseRetryAfter(header); got != 0 {
+		t.Errorf("parseRetryAfter(%q) = %s, want 0 for a past date", header, got)
+	}
+}